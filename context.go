@@ -0,0 +1,97 @@
+package logger
+
+import (
+	"context"
+	"sync"
+)
+
+// ctxKey is an unexported type for context.Context keys defined in this
+// package, so they can't collide with keys defined elsewhere.
+type ctxKey string
+
+// loggerCtxKey is the context.Context key that NewContext/FromContext store
+// a Logger under.
+const loggerCtxKey ctxKey = "logger"
+
+// defaultContextFields are the context.Context keys that WithContext always
+// pulls onto a logger as top-level fields, keyed by the field name they're
+// logged under.
+var defaultContextFields = map[string]ctxKey{
+	"request_id": "request_id",
+	"trace_id":   "trace_id",
+	"span_id":    "span_id",
+	"user_id":    "user_id",
+	"app_name":   "app_name",
+}
+
+var (
+	contextFieldsMu sync.RWMutex
+	contextFields_  = cloneContextFields(defaultContextFields)
+)
+
+// RegisterContextKey adds an additional context.Context key to the set that
+// WithContext pulls onto a logger as a top-level field. It's meant to be
+// called during service start up, for keys beyond the default request_id /
+// trace_id / span_id / user_id / app_name set.
+func RegisterContextKey(field string) {
+	contextFieldsMu.Lock()
+	defer contextFieldsMu.Unlock()
+
+	contextFields_[field] = ctxKey(field)
+}
+
+func cloneContextFields(fields map[string]ctxKey) map[string]ctxKey {
+	cloned := make(map[string]ctxKey, len(fields))
+
+	for field, key := range fields {
+		cloned[field] = key
+	}
+
+	return cloned
+}
+
+// WithField returns a copy of ctx carrying value under field, so a later
+// WithContext call picks it up as a top-level field on the derived logger.
+// field must be one of the default fields (request_id, trace_id, span_id,
+// user_id, app_name) or one previously passed to RegisterContextKey --
+// otherwise WithContext has no key to look the value up under and it's
+// silently ignored.
+func WithField(ctx context.Context, field string, value interface{}) context.Context {
+	return context.WithValue(ctx, ctxKey(field), value)
+}
+
+// contextFields reads the registered context keys off of ctx and returns the
+// ones that were present as a field map.
+func contextFields(ctx context.Context) map[string]interface{} {
+	if ctx == nil {
+		return nil
+	}
+
+	contextFieldsMu.RLock()
+	defer contextFieldsMu.RUnlock()
+
+	fields := make(map[string]interface{})
+
+	for field, key := range contextFields_ {
+		if value := ctx.Value(key); value != nil {
+			fields[field] = value
+		}
+	}
+
+	return fields
+}
+
+// NewContext returns a copy of ctx carrying l, so it can be retrieved later
+// with FromContext. Use this to thread a request-scoped logger through a
+// call chain.
+func NewContext(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, loggerCtxKey, l)
+}
+
+// FromContext returns the Logger previously attached to ctx with
+// NewContext, along with whether one was found.
+func FromContext(ctx context.Context) (Logger, bool) {
+	l, ok := ctx.Value(loggerCtxKey).(Logger)
+
+	return l, ok
+}
@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWithContext(t *testing.T) {
+	testLogger, err := ConfigureLogger("deskpass-logger-test", "", "development")
+
+	if err != nil {
+		t.Fatalf("Failed to configure logger: %v", err)
+	}
+
+	ctx := WithField(context.Background(), "request_id", "req-123")
+	scoped := testLogger.WithContext(ctx)
+
+	scoped.Info("This is a test info message scoped to a request", nil)
+}
+
+func TestWithFieldRegisteredKey(t *testing.T) {
+	RegisterContextKey("tenant_id")
+
+	testLogger, err := ConfigureLogger("deskpass-logger-test", "", "development")
+
+	if err != nil {
+		t.Fatalf("Failed to configure logger: %v", err)
+	}
+
+	ctx := WithField(context.Background(), "tenant_id", "tenant-456")
+	fields := contextFields(ctx)
+
+	if fields["tenant_id"] != "tenant-456" {
+		t.Fatalf("Expected tenant_id to be pulled off the context, got: %v", fields["tenant_id"])
+	}
+
+	testLogger.WithContext(ctx).Info("This is a test info message scoped to a registered context key", nil)
+}
+
+func TestNewContextFromContext(t *testing.T) {
+	testLogger, err := ConfigureLogger("deskpass-logger-test", "", "development")
+
+	if err != nil {
+		t.Fatalf("Failed to configure logger: %v", err)
+	}
+
+	ctx := NewContext(context.Background(), testLogger)
+
+	fromCtx, ok := FromContext(ctx)
+
+	if !ok {
+		t.Fatal("Expected to find a logger attached to the context")
+	}
+
+	fromCtx.Info("This is a test info message from a logger pulled off a context", nil)
+
+	if _, ok := FromContext(context.Background()); ok {
+		t.Fatal("Expected no logger to be found on a plain context")
+	}
+}
@@ -0,0 +1,153 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// levelState holds the current minimum level for a logger, guarded by its
+// own lock so it can be shared (by pointer) between a logger and every
+// child derived from it via With/WithContext -- they all log at the same
+// level unless split off into their own package with Named.
+type levelState struct {
+	mu    sync.RWMutex
+	level zerolog.Level
+}
+
+func (s *levelState) enabled(eventLevel zerolog.Level) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return eventLevel >= s.level
+}
+
+func (s *levelState) get() zerolog.Level {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.level
+}
+
+func (s *levelState) set(level zerolog.Level) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.level = level
+}
+
+// levelRegistry tracks every named sub-logger derived from a given root
+// logger, so LevelHandler can look one up by name. It's shared by pointer
+// across a logger and everything derived from it.
+type levelRegistry struct {
+	mu      sync.RWMutex
+	loggers map[string]*zerologLogger
+}
+
+// SetLevel atomically changes the minimum level this logger will emit.
+func (l *zerologLogger) SetLevel(level string) error {
+	zlLevel, ok := validLogLevels[level]
+
+	if !ok {
+		return fmt.Errorf("invalid log level: %s", level)
+	}
+
+	l.levelState.set(zlLevel)
+
+	return nil
+}
+
+// Level returns the logger's current minimum level.
+func (l *zerologLogger) Level() string {
+	return levelName(l.levelState.get())
+}
+
+func levelName(level zerolog.Level) string {
+	for name, candidate := range validLogLevels {
+		if candidate == level {
+			return name
+		}
+	}
+
+	return "info"
+}
+
+// Named returns the child logger scoped to pkg, creating it (at the
+// parent's current level) the first time it's requested.
+func (l *zerologLogger) Named(pkg string) Logger {
+	l.registry.mu.Lock()
+	defer l.registry.mu.Unlock()
+
+	if existing, ok := l.registry.loggers[pkg]; ok {
+		return existing
+	}
+
+	namedLocalLogger := l.localLogger.With().Str("pkg", pkg).Logger()
+
+	child := &zerologLogger{
+		appName:        l.appName,
+		name:           pkg,
+		localLogger:    &namedLocalLogger,
+		localLoggerFns: buildLocalLoggerFns(&namedLocalLogger),
+		sinks:          l.sinks,
+		// Also bake "pkg" into fields, not just the local zerolog context,
+		// so it's forwarded to every Sink -- not just visible on stdout.
+		fields:     mergeFields(l.fields, map[string]interface{}{"pkg": pkg}),
+		levelState: &levelState{level: l.levelState.get()},
+		registry:   l.registry,
+	}
+
+	l.registry.loggers[pkg] = child
+
+	return child
+}
+
+// levelHandlerBody is the JSON document read and written by LevelHandler.
+type levelHandlerBody struct {
+	Level string `json:"level"`
+}
+
+// LevelHandler returns an http.Handler for reading and writing log levels
+// at runtime. GET returns the current level of l, or of a named sub-logger
+// if a "pkg" query parameter is given; PUT sets it from a JSON body of the
+// form {"level": "debug"}.
+func LevelHandler(l Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target := l
+
+		if pkg := r.URL.Query().Get("pkg"); pkg != "" {
+			target = l.Named(pkg)
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			writeLevelJSON(w, http.StatusOK, target.Level())
+		case http.MethodPut:
+			var body levelHandlerBody
+
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			if err := target.SetLevel(body.Level); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			writeLevelJSON(w, http.StatusOK, target.Level())
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func writeLevelJSON(w http.ResponseWriter, status int, level string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(levelHandlerBody{Level: level})
+}
@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSetLevelAndNamed(t *testing.T) {
+	testLogger, err := ConfigureLogger("deskpass-logger-test", "", "production")
+
+	if err != nil {
+		t.Fatalf("Failed to configure logger: %v", err)
+	}
+
+	if testLogger.Level() != "info" {
+		t.Fatalf("Expected default production level to be info, got %s", testLogger.Level())
+	}
+
+	if err := testLogger.SetLevel("debug"); err != nil {
+		t.Fatalf("Failed to set level: %v", err)
+	}
+
+	if testLogger.Level() != "debug" {
+		t.Fatalf("Expected level to be debug after SetLevel, got %s", testLogger.Level())
+	}
+
+	if err := testLogger.SetLevel("not-a-level"); err == nil {
+		t.Fatal("Expected an error setting an invalid level")
+	}
+
+	worker := testLogger.Named("worker")
+
+	if worker.Level() != "debug" {
+		t.Fatalf("Expected Named logger to inherit the parent's level, got %s", worker.Level())
+	}
+
+	worker.SetLevel("warn")
+
+	if testLogger.Level() != "debug" {
+		t.Fatal("Expected changing a Named logger's level to leave the parent's level untouched")
+	}
+
+	if testLogger.Named("worker").Level() != "warn" {
+		t.Fatal("Expected repeated calls to Named with the same name to return the same logger")
+	}
+}
+
+func TestNamedForwardsPkgToSinks(t *testing.T) {
+	var buf bytes.Buffer
+	syncedBuf := &syncWriter{w: &buf}
+
+	testLogger, err := ConfigureLogger("deskpass-logger-test", "", "development", WithWriterSink(syncedBuf))
+
+	if err != nil {
+		t.Fatalf("Failed to configure logger: %v", err)
+	}
+
+	worker := testLogger.Named("worker")
+	worker.Info("This is a test info message from a named sub-logger", nil)
+
+	if err := testLogger.Flush(context.Background()); err != nil {
+		t.Fatalf("Failed to flush logger: %v", err)
+	}
+
+	line := strings.TrimSpace(syncedBuf.String())
+
+	var decoded map[string]interface{}
+
+	if err := json.Unmarshal([]byte(line), &decoded); err != nil {
+		t.Fatalf("Failed to decode sink event: %v", err)
+	}
+
+	meta, _ := decoded["meta"].(map[string]interface{})
+
+	if meta["pkg"] != "worker" {
+		t.Fatalf("Expected the sink event's meta to carry pkg=worker, got: %v", decoded["meta"])
+	}
+}
+
+func TestLevelHandler(t *testing.T) {
+	testLogger, err := ConfigureLogger("deskpass-logger-test", "", "production")
+
+	if err != nil {
+		t.Fatalf("Failed to configure logger: %v", err)
+	}
+
+	handler := LevelHandler(testLogger)
+
+	putReq := httptest.NewRequest(http.MethodPut, "/?pkg=worker", strings.NewReader(`{"level":"debug"}`))
+	putRec := httptest.NewRecorder()
+	handler.ServeHTTP(putRec, putReq)
+
+	if putRec.Code != http.StatusOK {
+		t.Fatalf("Expected 200 from PUT, got %d: %s", putRec.Code, putRec.Body.String())
+	}
+
+	getReq := httptest.NewRequest(http.MethodGet, "/?pkg=worker", nil)
+	getRec := httptest.NewRecorder()
+	handler.ServeHTTP(getRec, getReq)
+
+	if !strings.Contains(getRec.Body.String(), "debug") {
+		t.Fatalf("Expected GET response to report the level set via PUT, got: %s", getRec.Body.String())
+	}
+
+	if testLogger.Named("worker").Level() != "debug" {
+		t.Fatal("Expected the worker sub-logger set through the handler to be reachable via Named")
+	}
+}
@@ -7,19 +7,78 @@
 package logger
 
 import (
+	"context"
 	"fmt"
 	"github.com/rs/zerolog"
-	"log/syslog"
 	"os"
+	"time"
 )
 
-// Basic logger struct that will be returned by ConfigureLogger and contains
-// raw local and remote loggers along with maps of functions for each log level
-type Logger struct {
-	localLogger     *zerolog.Logger
-	remoteLogger    *zerolog.Logger
-	localLoggerFns  map[string]func() *zerolog.Event
-	remoteLoggerFns map[string]func() *zerolog.Event
+// Logger is the interface implemented by every logger returned from this
+// package. Depending on a Logger instead of the concrete zerolog-backed type
+// lets callers substitute a no-op/mock logger in tests or wrap it with
+// middleware without changing call sites.
+type Logger interface {
+	Debug(message string, meta map[string]interface{})
+	Info(message string, meta map[string]interface{})
+	Warn(message string, meta map[string]interface{})
+	Error(message string, err error, meta map[string]interface{})
+
+	// With returns a child logger that includes the given fields on every
+	// subsequent log event, local or remote.
+	With(fields map[string]interface{}) Logger
+
+	// WithContext returns a child logger with fields pulled out of ctx (see
+	// contextFields for the set of keys that are recognized).
+	WithContext(ctx context.Context) Logger
+
+	// Flush blocks until every sink has drained its queued events, or ctx is
+	// done. Services should call this during shutdown so buffered events
+	// aren't lost.
+	Flush(ctx context.Context) error
+
+	// SetLevel atomically changes the minimum level this logger (and,
+	// unless created via Named, any logger derived from it with With or
+	// WithContext) will emit.
+	SetLevel(level string) error
+
+	// Level returns the logger's current minimum level.
+	Level() string
+
+	// Named returns a child logger scoped to a package/subsystem, tagged
+	// with a "pkg" field and with its own independently adjustable level.
+	// Calling Named with the same pkg on any logger derived from the same
+	// root returns the same child logger.
+	Named(pkg string) Logger
+
+	// Close releases every sink's resources -- stopping its worker
+	// goroutine and closing any underlying connection or file -- and
+	// should be called once during shutdown, after a final Flush. Unlike
+	// Flush, it makes only one delivery attempt for each event still
+	// queued; a failed attempt is not retried.
+	Close() error
+}
+
+// zerologLogger is the default Logger implementation. It logs locally via
+// zerolog and fans each event out to a slice of remote Sinks.
+type zerologLogger struct {
+	appName        string
+	name           string
+	localLogger    *zerolog.Logger
+	localLoggerFns map[string]func() *zerolog.Event
+	sinks          []Sink
+	fields         map[string]interface{}
+	levelState     *levelState
+	registry       *levelRegistry
+}
+
+// sinkLevelNames maps the internal level codes used to index
+// localLoggerFns onto the lowercase level names passed to Sink.Write.
+var sinkLevelNames = map[string]string{
+	"Debug": "debug",
+	"Info":  "info",
+	"Warn":  "warn",
+	"Err":   "error",
 }
 
 var validLogLevels = map[string]zerolog.Level{
@@ -29,28 +88,94 @@ var validLogLevels = map[string]zerolog.Level{
 	"error": zerolog.ErrorLevel,
 }
 
+// commonLogLevels maps the internal level codes passed to commonLog onto
+// the zerolog.Level used to decide whether an event is enabled.
+var commonLogLevels = map[string]zerolog.Level{
+	"Debug": zerolog.DebugLevel,
+	"Info":  zerolog.InfoLevel,
+	"Warn":  zerolog.WarnLevel,
+	"Err":   zerolog.ErrorLevel,
+}
+
 // Define basic logging functions for each log level, all of which just call
 // commonLog with the appropriate level
-func (l *Logger) Debug(message string, meta map[string]interface{}) {
+func (l *zerologLogger) Debug(message string, meta map[string]interface{}) {
 	l.commonLog(message, nil, &meta, "Debug")
 }
 
-func (l *Logger) Info(message string, meta map[string]interface{}) {
+func (l *zerologLogger) Info(message string, meta map[string]interface{}) {
 	l.commonLog(message, nil, &meta, "Info")
 }
 
-func (l *Logger) Warn(message string, meta map[string]interface{}) {
+func (l *zerologLogger) Warn(message string, meta map[string]interface{}) {
 	l.commonLog(message, nil, &meta, "Warn")
 }
 
-func (l *Logger) Error(message string, err error, meta map[string]interface{}) {
+func (l *zerologLogger) Error(message string, err error, meta map[string]interface{}) {
 	l.commonLog(message, err, &meta, "Err")
 }
 
+// With returns a child logger that bakes the given fields into every event
+// it logs from here on, in addition to whatever meta is passed to a specific
+// Debug/Info/Warn/Error call.
+func (l *zerologLogger) With(fields map[string]interface{}) Logger {
+	child := *l
+	child.fields = mergeFields(l.fields, fields)
+
+	return &child
+}
+
+// WithContext returns a child logger populated with fields read off of ctx.
+func (l *zerologLogger) WithContext(ctx context.Context) Logger {
+	return l.With(contextFields(ctx))
+}
+
+// Flush drains every sink's queue, returning the first error encountered (if
+// any), or ctx.Err() if ctx is done before all sinks finish draining.
+func (l *zerologLogger) Flush(ctx context.Context) error {
+	var firstErr error
+
+	for _, sink := range l.sinks {
+		flushable, ok := sink.(flusher)
+
+		if !ok {
+			continue
+		}
+
+		if err := flushable.Flush(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// Close closes every sink, returning the first error encountered (if any).
+// Each sink makes one delivery attempt per event still queued, without the
+// retries Flush waits for, so callers should Flush before Close if queued
+// events need to be delivered.
+func (l *zerologLogger) Close() error {
+	var firstErr error
+
+	for _, sink := range l.sinks {
+		if err := sink.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
 // Main logging function that all other logging functions call. This is where
-// the actual logging happens, and it's also where the remote logging is
-// handled if it's been set up.
-func (l *Logger) commonLog(message string, err error, meta *map[string]interface{}, level string) {
+// the actual logging happens, and it's also where fan-out to the configured
+// sinks is handled.
+func (l *zerologLogger) commonLog(message string, err error, meta *map[string]interface{}, level string) {
+	if !l.levelState.enabled(commonLogLevels[level]) {
+		return
+	}
+
+	combinedMeta := mergeFields(l.fields, metaOrEmpty(meta))
+
 	var localLogger *zerolog.Event
 
 	// Create the local logger--this has to be handled slightly differently with
@@ -62,40 +187,42 @@ func (l *Logger) commonLog(message string, err error, meta *map[string]interface
 			localLogger = l.localLoggerFns[level]()
 		}
 
-		// Tack on additional metadata if it's been provided
-		if meta != nil {
-			localLogger = localLogger.Dict("meta", buildDictFromMeta(meta, l))
+		// Tack on additional metadata if there is any to add
+		if len(combinedMeta) > 0 {
+			localLogger = localLogger.Dict("meta", buildDictFromMeta(combinedMeta))
 		}
 
 		// Log the message locally
 		localLogger.Msg(message)
 	}
 
-	// Then deal with remote logging if it's been set up
-	var remoteLogger *zerolog.Event
+	// Then fan the event out to every configured sink
+	if len(l.sinks) > 0 {
+		ts := time.Now()
+		sinkLevel := sinkLevelNames[level]
 
-	if l.remoteLogger != nil {
-		if level == "Err" {
-			remoteLogger = l.remoteLogger.Err(err)
-		} else {
-			remoteLogger = l.remoteLoggerFns[level]()
+		if err != nil {
+			combinedMeta = mergeFields(combinedMeta, map[string]interface{}{"error": err.Error()})
 		}
 
-		if meta != nil {
-			remoteLogger = remoteLogger.Dict("meta", buildDictFromMeta(meta, l))
+		for _, sink := range l.sinks {
+			if writeErr := sink.Write(sinkLevel, ts, l.appName, message, combinedMeta); writeErr != nil {
+				l.localLogger.Error().Err(writeErr).Msg("Failed to write log event to sink")
+			}
 		}
-
-		// Log the message remotely
-		remoteLogger.Msg(message)
 	}
 }
 
-// Main function to set up the logger. appName is the name of the service, and
-// remoteLoggerURL is the URL for the remote logger (if it's being used). The
-// environment is used to determine whether to use JSON logging and to set up
-// the remote logger. Will return a Logger struct that can be used to log
-// messages.
-func ConfigureLogger(appName string, remoteLoggerURL string, environment string) *Logger {
+// Main function to set up the logger. appName is the name of the service,
+// and remoteLoggerURL is the address of a UDP syslog server (if one is being
+// used) -- it's a shortcut for WithSyslogSink("udp", remoteLoggerURL, nil)
+// with the syslog tag set to "appName-environment", kept for backward
+// compatibility. The environment is used to
+// determine whether to use JSON logging and to set the default log level.
+// Additional sinks can be attached with opts. Will return a Logger that can
+// be used to log messages, along with an error if the local writer,
+// LOG_LEVEL, or any sink could not be set up.
+func ConfigureLogger(appName string, remoteLoggerURL string, environment string, opts ...Option) (Logger, error) {
 	// Set up local logger for starters
 	zerolog.TimeFieldFormat = zerolog.TimeFormatUnixMicro
 
@@ -106,7 +233,7 @@ func ConfigureLogger(appName string, remoteLoggerURL string, environment string)
 		if _, exists := validLogLevels[os.Getenv("LOG_LEVEL")]; exists {
 			logLevel = os.Getenv("LOG_LEVEL")
 		} else {
-			fmt.Println("Invalid log level specified:", os.Getenv("LOG_LEVEL"))
+			return nil, fmt.Errorf("invalid log level specified: %s", os.Getenv("LOG_LEVEL"))
 		}
 	} else {
 		if environment == "development" {
@@ -114,7 +241,10 @@ func ConfigureLogger(appName string, remoteLoggerURL string, environment string)
 		}
 	}
 
-	zerolog.SetGlobalLevel(validLogLevels[logLevel])
+	// zerolog's own level gate is global, which is exactly what per-package
+	// levels need to not be -- leave it permissive and do level filtering
+	// ourselves in commonLog, scoped to each logger's own levelState.
+	zerolog.SetGlobalLevel(zerolog.TraceLevel)
 
 	// Then set up the local logger for printing to stdout
 	localLogger := zerolog.New(os.Stdout).With().Timestamp().Str("app", appName).Logger()
@@ -126,63 +256,86 @@ func ConfigureLogger(appName string, remoteLoggerURL string, environment string)
 
 	localLogger.Debug().Msg(fmt.Sprintf("Setting up logger for %s in %s environment", appName, environment))
 
-	// Set up the combined local/remote logger that will be returned for use
-	combinedLogger := Logger{
-		localLogger:  &localLogger,
-		remoteLogger: nil,
-		localLoggerFns: map[string]func() *zerolog.Event{
-			"Debug": localLogger.Debug,
-			"Info":  localLogger.Info,
-			"Warn":  localLogger.Warn,
-		},
-		remoteLoggerFns: map[string]func() *zerolog.Event{
-			"Debug": nil,
-			"Info":  nil,
-			"Warn":  nil,
-		},
+	// Set up the combined logger that will be returned for use
+	combinedLogger := zerologLogger{
+		appName:        appName,
+		localLogger:    &localLogger,
+		localLoggerFns: buildLocalLoggerFns(&localLogger),
+		levelState:     &levelState{level: validLogLevels[logLevel]},
+		registry:       &levelRegistry{loggers: map[string]*zerologLogger{}},
 	}
 
 	if remoteLoggerURL != "" {
-		// Configure the remote logger
-		remoteLog, err := syslog.Dial(
-			"udp",
-			remoteLoggerURL,
-			syslog.LOG_EMERG,
-			appName+"-"+environment,
-		)
-
-		if err == nil {
-			remoteLogger := zerolog.New(zerolog.SyslogLevelWriter(remoteLog)).With().Timestamp().Str("app", appName).Logger()
-
-			if environment == "development" {
-				remoteLogger = remoteLogger.Output(zerolog.ConsoleWriter{Out: remoteLog})
-			}
+		// Preserve the pre-Sink behavior of remoteLoggerURL as a UDP syslog
+		// sink tagged "appName-environment", matching what syslog.Dial used
+		// to be called with.
+		opts = append([]Option{withSyslogSinkTag("udp", remoteLoggerURL, nil, appName+"-"+environment)}, opts...)
+	}
 
-			combinedLogger.remoteLogger = &remoteLogger
+	for _, opt := range opts {
+		if err := opt(&combinedLogger); err != nil {
+			// Roll back any sinks a prior option already attached, so their
+			// worker goroutines and connections don't leak past an error
+			// the caller has no Logger left to Close.
+			combinedLogger.Close()
 
-			// Save the remote logger functions into the logger struct so that they
-			// can be referenced by string later
-			combinedLogger.remoteLoggerFns["Debug"] = remoteLogger.Debug
-			combinedLogger.remoteLoggerFns["Info"] = remoteLogger.Info
-			combinedLogger.remoteLoggerFns["Warn"] = remoteLogger.Warn
-		} else {
-			localLogger.Err(err).Msg("Failed to set up remote logger!")
+			return nil, fmt.Errorf("failed to configure logger: %w", err)
 		}
 	}
 
 	// Configure the general logger
-	return &combinedLogger
+	return &combinedLogger, nil
+}
+
+// buildLocalLoggerFns captures the Debug/Info/Warn method values off of a
+// *zerolog.Logger so commonLog can look them up by level string. Used both
+// when a logger is first configured and when Named derives a sub-logger
+// with its own zerolog.Logger.
+func buildLocalLoggerFns(localLogger *zerolog.Logger) map[string]func() *zerolog.Event {
+	return map[string]func() *zerolog.Event{
+		"Debug": localLogger.Debug,
+		"Info":  localLogger.Info,
+		"Warn":  localLogger.Warn,
+	}
 }
 
 // Helper function to just flip through meta values and build a Dict from the
 // values. This is used to add metadata to log messages.
-func buildDictFromMeta(meta *map[string]interface{}, logger *Logger) *zerolog.Event {
+func buildDictFromMeta(meta map[string]interface{}) *zerolog.Event {
 	// Flip through meta fields, building Dict that can be passed to logger
 	loggerDict := zerolog.Dict()
 
-	for key, value := range *meta {
+	for key, value := range meta {
 		loggerDict.Any(key, value)
 	}
 
 	return loggerDict
 }
+
+// metaOrEmpty returns the map pointed to by meta, or an empty map if meta is
+// nil or points to a nil map, so callers don't have to nil-check it.
+func metaOrEmpty(meta *map[string]interface{}) map[string]interface{} {
+	if meta == nil || *meta == nil {
+		return map[string]interface{}{}
+	}
+
+	return *meta
+}
+
+// mergeFields combines baked-in fields with per-call meta, with per-call
+// meta taking precedence on key collisions. It always returns a fresh map,
+// never base or overlay themselves, so a logger returned from With can't end
+// up aliasing a map the caller goes on to mutate or reuse.
+func mergeFields(base map[string]interface{}, overlay map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(overlay))
+
+	for key, value := range base {
+		merged[key] = value
+	}
+
+	for key, value := range overlay {
+		merged[key] = value
+	}
+
+	return merged
+}
@@ -21,8 +21,17 @@ func TestMain(m *testing.M) {
 }
 
 func TestLocalLogger(t *testing.T) {
-	testDevLogger := ConfigureLogger("deskpass-logger-test", "", "development")
-	testProdLogger := ConfigureLogger("deskpass-logger-test", "", "production")
+	testDevLogger, err := ConfigureLogger("deskpass-logger-test", "", "development")
+
+	if err != nil {
+		t.Fatalf("Failed to configure dev logger: %v", err)
+	}
+
+	testProdLogger, err := ConfigureLogger("deskpass-logger-test", "", "production")
+
+	if err != nil {
+		t.Fatalf("Failed to configure prod logger: %v", err)
+	}
 
 	standardTestSuite(t, testDevLogger)
 	standardTestSuite(t, testProdLogger)
@@ -37,14 +46,46 @@ func TestRemoteLogger(t *testing.T) {
 
 	os.Setenv("REMOTE_LOG_ONLY", "1")
 
-	testDevLogger := ConfigureLogger("deskpass-logger-test", loggingURL, "development")
-	testProdLogger := ConfigureLogger("deskpass-logger-test", loggingURL, "production")
+	testDevLogger, err := ConfigureLogger("deskpass-logger-test", loggingURL, "development")
+
+	if err != nil {
+		t.Fatalf("Failed to configure dev logger: %v", err)
+	}
+
+	testProdLogger, err := ConfigureLogger("deskpass-logger-test", loggingURL, "production")
+
+	if err != nil {
+		t.Fatalf("Failed to configure prod logger: %v", err)
+	}
 
 	standardTestSuite(t, testDevLogger)
 	standardTestSuite(t, testProdLogger)
 }
 
-func standardTestSuite(t *testing.T, logger *Logger) {
+func TestWithDoesNotAliasCallerMap(t *testing.T) {
+	testLogger, err := ConfigureLogger("deskpass-logger-test", "", "development")
+
+	if err != nil {
+		t.Fatalf("Failed to configure logger: %v", err)
+	}
+
+	fields := map[string]interface{}{"a": 1}
+	scoped := testLogger.With(fields)
+
+	fields["a"] = 999
+
+	zl, ok := scoped.(*zerologLogger)
+
+	if !ok {
+		t.Fatal("Expected With to return a *zerologLogger")
+	}
+
+	if zl.fields["a"] != 1 {
+		t.Fatalf("Expected the child logger's baked-in fields to be unaffected by mutating the caller's map, got: %v", zl.fields["a"])
+	}
+}
+
+func standardTestSuite(t *testing.T, logger Logger) {
 	testError := fmt.Errorf("This is a test error")
 	testMeta := map[string]interface{}{
 		"testKey":  "testString",
@@ -0,0 +1,295 @@
+package logger
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// RotateOptions configures the rollover behavior of a file Sink installed
+// with WithFileOutput. The zero value disables rollover entirely -- the
+// sink just appends to path forever.
+type RotateOptions struct {
+	// MaxSizeBytes rotates the current file once it exceeds this many
+	// bytes. Zero disables size-based rollover.
+	MaxSizeBytes int64
+
+	// Interval rotates the current file once it's been open longer than
+	// this (e.g. time.Hour or 24*time.Hour). Zero disables time-based
+	// rollover.
+	Interval time.Duration
+
+	// MaxBackups is the number of rotated files to keep, oldest first.
+	// Zero keeps them all.
+	MaxBackups int
+
+	// MaxAge removes rotated files older than this. Zero keeps them
+	// regardless of age.
+	MaxAge time.Duration
+
+	// Compress gzips a file as soon as it's rotated out.
+	Compress bool
+}
+
+// WithFileOutput attaches a sink that writes JSON lines to a local file at
+// path, rolling it over according to opts. It composes with the other sinks
+// rather than replacing them, so it's typically used alongside the default
+// stdout logging and/or a remote sink.
+func WithFileOutput(path string, opts RotateOptions) Option {
+	return func(l *zerologLogger) error {
+		sink, err := newFileSink(path, opts)
+
+		if err != nil {
+			return fmt.Errorf("failed to set up file output: %w", err)
+		}
+
+		l.sinks = append(l.sinks, newAsyncSink(sink))
+
+		return nil
+	}
+}
+
+// fileSink writes events to a local file, rotating it per RotateOptions.
+// The file handle is only ever touched under mu, so Write and a SIGHUP
+// reopen can never race on a closed fd.
+type fileSink struct {
+	path string
+	opts RotateOptions
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+
+	hup     chan os.Signal
+	hupDone chan struct{}
+}
+
+func newFileSink(path string, opts RotateOptions) (*fileSink, error) {
+	s := &fileSink{path: path, opts: opts}
+
+	if err := s.openLocked(); err != nil {
+		return nil, err
+	}
+
+	s.hup = make(chan os.Signal, 1)
+	s.hupDone = make(chan struct{})
+
+	signal.Notify(s.hup, syscall.SIGHUP)
+
+	go s.watchHUP()
+
+	return s, nil
+}
+
+// watchHUP reopens the log file whenever SIGHUP is received, so external
+// log rotation tools (logrotate and friends) keep working against this
+// file the same way they would against a plain os.Stdout redirect.
+func (s *fileSink) watchHUP() {
+	for {
+		select {
+		case <-s.hup:
+			s.mu.Lock()
+			s.closeLocked()
+			s.openLocked()
+			s.mu.Unlock()
+		case <-s.hupDone:
+			return
+		}
+	}
+}
+
+func (s *fileSink) openLocked() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	s.file = file
+	s.size = info.Size()
+	s.openedAt = time.Now()
+
+	return nil
+}
+
+func (s *fileSink) closeLocked() {
+	if s.file != nil {
+		s.file.Close()
+		s.file = nil
+	}
+}
+
+func (s *fileSink) Write(level string, ts time.Time, appName string, msg string, fields map[string]interface{}) error {
+	body, err := json.Marshal(sinkEventJSON(level, ts, appName, msg, fields))
+
+	if err != nil {
+		return fmt.Errorf("file sink: %w", err)
+	}
+
+	line := append(body, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.needsRotateLocked() {
+		if err := s.rotateLocked(); err != nil {
+			return fmt.Errorf("file sink: %w", err)
+		}
+	}
+
+	n, err := s.file.Write(line)
+
+	if err != nil {
+		return fmt.Errorf("file sink: %w", err)
+	}
+
+	s.size += int64(n)
+
+	return nil
+}
+
+func (s *fileSink) needsRotateLocked() bool {
+	if s.opts.MaxSizeBytes > 0 && s.size >= s.opts.MaxSizeBytes {
+		return true
+	}
+
+	if s.opts.Interval > 0 && time.Since(s.openedAt) >= s.opts.Interval {
+		return true
+	}
+
+	return false
+}
+
+// rotateLocked closes the current file, moves it aside under a timestamped
+// name (compressing it if requested), opens a fresh file at the original
+// path, and prunes old backups per MaxBackups/MaxAge.
+func (s *fileSink) rotateLocked() error {
+	s.closeLocked()
+
+	backupPath := s.path + "." + time.Now().Format("20060102T150405.000000000")
+
+	if err := os.Rename(s.path, backupPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if s.opts.Compress {
+		if err := compressFile(backupPath); err != nil {
+			return err
+		}
+	}
+
+	if err := s.openLocked(); err != nil {
+		return err
+	}
+
+	return s.pruneBackups()
+}
+
+func compressFile(path string) error {
+	src, err := os.Open(path)
+
+	if err != nil {
+		return err
+	}
+
+	defer src.Close()
+
+	dst, err := os.Create(path + ".gz")
+
+	if err != nil {
+		return err
+	}
+
+	gzWriter := gzip.NewWriter(dst)
+
+	if _, err := io.Copy(gzWriter, src); err != nil {
+		gzWriter.Close()
+		dst.Close()
+		return err
+	}
+
+	if err := gzWriter.Close(); err != nil {
+		dst.Close()
+		return err
+	}
+
+	if err := dst.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(path)
+}
+
+// pruneBackups removes rotated files beyond MaxBackups (oldest first) and
+// any older than MaxAge, whichever applies.
+func (s *fileSink) pruneBackups() error {
+	if s.opts.MaxBackups <= 0 && s.opts.MaxAge <= 0 {
+		return nil
+	}
+
+	matches, err := filepath.Glob(s.path + ".*")
+
+	if err != nil {
+		return err
+	}
+
+	sort.Strings(matches)
+
+	if s.opts.MaxAge > 0 {
+		cutoff := time.Now().Add(-s.opts.MaxAge)
+		kept := matches[:0]
+
+		for _, match := range matches {
+			info, err := os.Stat(match)
+
+			if err != nil || info.ModTime().Before(cutoff) {
+				os.Remove(match)
+				continue
+			}
+
+			kept = append(kept, match)
+		}
+
+		matches = kept
+	}
+
+	if s.opts.MaxBackups > 0 && len(matches) > s.opts.MaxBackups {
+		for _, match := range matches[:len(matches)-s.opts.MaxBackups] {
+			os.Remove(match)
+		}
+	}
+
+	return nil
+}
+
+func (s *fileSink) Close() error {
+	close(s.hupDone)
+	signal.Stop(s.hup)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closeLocked()
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWithFileOutputRotatesBySize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	testLogger, err := ConfigureLogger("deskpass-logger-test", "", "production", WithFileOutput(path, RotateOptions{
+		MaxSizeBytes: 1,
+		MaxBackups:   1,
+	}))
+
+	if err != nil {
+		t.Fatalf("Failed to configure logger: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		testLogger.Info("This is a test message that should trigger size-based rollover", nil)
+	}
+
+	if err := testLogger.Flush(context.Background()); err != nil {
+		t.Fatalf("Failed to flush logger: %v", err)
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("Expected current log file to exist: %v", err)
+	}
+
+	matches, err := filepath.Glob(path + ".*")
+
+	if err != nil {
+		t.Fatalf("Failed to glob for rotated files: %v", err)
+	}
+
+	if len(matches) == 0 {
+		t.Fatal("Expected at least one rotated backup file after exceeding MaxSizeBytes")
+	}
+
+	if len(matches) > 1 {
+		t.Fatalf("Expected MaxBackups to cap rotated files at 1, got %d", len(matches))
+	}
+}
+
+func TestWithFileOutputCompresses(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	testLogger, err := ConfigureLogger("deskpass-logger-test", "", "production", WithFileOutput(path, RotateOptions{
+		MaxSizeBytes: 1,
+		Compress:     true,
+	}))
+
+	if err != nil {
+		t.Fatalf("Failed to configure logger: %v", err)
+	}
+
+	testLogger.Info("This is the first message", nil)
+	testLogger.Info("This is the second message, which should trigger a rotation", nil)
+
+	if err := testLogger.Flush(context.Background()); err != nil {
+		t.Fatalf("Failed to flush logger: %v", err)
+	}
+
+	matches, err := filepath.Glob(path + ".*.gz")
+
+	if err != nil {
+		t.Fatalf("Failed to glob for compressed backups: %v", err)
+	}
+
+	if len(matches) == 0 {
+		t.Fatal("Expected a gzip-compressed rotated file")
+	}
+}
+
+func TestFileSinkNeedsRotate(t *testing.T) {
+	sink := &fileSink{opts: RotateOptions{Interval: time.Millisecond}, openedAt: time.Now().Add(-time.Hour)}
+
+	if !sink.needsRotateLocked() {
+		t.Fatal("Expected a file opened well past Interval to need rotation")
+	}
+}
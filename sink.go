@@ -0,0 +1,424 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Sink is a destination for log events, beyond the local zerolog output
+// (which REMOTE_LOG_ONLY turns off). Implementations are expected to be safe
+// for concurrent use.
+type Sink interface {
+	Write(level string, ts time.Time, appName string, msg string, fields map[string]interface{}) error
+	Close() error
+}
+
+// flusher is implemented by sinks that buffer events and can be told to
+// drain that buffer on demand. asyncSink is the only implementation in this
+// package, but it's kept as a separate interface so Logger.Flush doesn't
+// need to know about asyncSink directly.
+type flusher interface {
+	Flush(ctx context.Context) error
+}
+
+// Option configures a Logger at ConfigureLogger time, most commonly to
+// attach a Sink.
+type Option func(*zerologLogger) error
+
+// WithSink attaches a caller-supplied Sink. Like the built-in sinks, it's
+// wrapped in a bounded async queue so a slow or unavailable sink can't block
+// the calling goroutine.
+func WithSink(sink Sink) Option {
+	return func(l *zerologLogger) error {
+		l.sinks = append(l.sinks, newAsyncSink(sink))
+		return nil
+	}
+}
+
+// WithSyslogSink attaches a syslog sink dialed over network (e.g. "tcp" or
+// "udp") to addr. If tlsConfig is non-nil, the connection is made over TLS
+// -- this is what Papertrail recommends instead of plain UDP.
+func WithSyslogSink(network string, addr string, tlsConfig *tls.Config) Option {
+	return withSyslogSinkTag(network, addr, tlsConfig, "")
+}
+
+// withSyslogSinkTag is WithSyslogSink plus an explicit syslog tag, overriding
+// the logger's appName. It backs the remoteLoggerURL shortcut on
+// ConfigureLogger, which historically tagged messages with
+// "appName-environment" rather than just appName.
+func withSyslogSinkTag(network string, addr string, tlsConfig *tls.Config, tag string) Option {
+	return func(l *zerologLogger) error {
+		sink := &syslogSink{network: network, addr: addr, tlsConfig: tlsConfig, tag: tag}
+
+		// Dial up front so ConfigureLogger reports an unreachable server.
+		if err := sink.connect(); err != nil {
+			return fmt.Errorf("failed to connect to syslog server: %w", err)
+		}
+
+		l.sinks = append(l.sinks, newAsyncSink(sink))
+		return nil
+	}
+}
+
+// WithHTTPSink attaches a sink that POSTs each event as a JSON document to
+// url.
+func WithHTTPSink(url string) Option {
+	return func(l *zerologLogger) error {
+		sink := &httpSink{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+		l.sinks = append(l.sinks, newAsyncSink(sink))
+		return nil
+	}
+}
+
+// WithWriterSink attaches a sink that writes each event to w as a line of
+// JSON. If w also implements io.Closer, it's closed when the sink is.
+func WithWriterSink(w io.Writer) Option {
+	return func(l *zerologLogger) error {
+		l.sinks = append(l.sinks, newAsyncSink(&writerSink{w: w}))
+		return nil
+	}
+}
+
+// sinkEvent is a snapshot of a single commonLog call, queued up for
+// asynchronous delivery to a Sink.
+type sinkEvent struct {
+	level   string
+	ts      time.Time
+	appName string
+	msg     string
+	fields  map[string]interface{}
+}
+
+const (
+	sinkQueueSize      = 256
+	sinkInitialBackoff = 100 * time.Millisecond
+	sinkMaxBackoff     = 30 * time.Second
+	sinkFlushPollEvery = 10 * time.Millisecond
+)
+
+// asyncSink wraps a Sink with a bounded queue and a single worker goroutine,
+// so a slow network sink can't block the logging call site. Writes that
+// fail are retried with exponential backoff until they succeed or the sink
+// is closed.
+type asyncSink struct {
+	inner Sink
+	queue chan sinkEvent
+	done  chan struct{}
+	wg    sync.WaitGroup
+
+	// inFlight counts deliveries that have been dequeued but haven't
+	// finished (including ones stuck retrying with backoff), so Flush can
+	// wait on it in addition to the queue itself.
+	inFlight int32
+}
+
+func newAsyncSink(inner Sink) *asyncSink {
+	s := &asyncSink{
+		inner: inner,
+		queue: make(chan sinkEvent, sinkQueueSize),
+		done:  make(chan struct{}),
+	}
+
+	s.wg.Add(1)
+	go s.run()
+
+	return s
+}
+
+func (s *asyncSink) Write(level string, ts time.Time, appName string, msg string, fields map[string]interface{}) error {
+	select {
+	case s.queue <- sinkEvent{level: level, ts: ts, appName: appName, msg: msg, fields: fields}:
+		return nil
+	default:
+		return fmt.Errorf("sink queue is full, dropping event")
+	}
+}
+
+func (s *asyncSink) run() {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case event := <-s.queue:
+			s.deliverTracked(event)
+		case <-s.done:
+			// Drain whatever is left in the queue before exiting so a Close
+			// right after a burst of Writes doesn't lose events outright.
+			for {
+				select {
+				case event := <-s.queue:
+					s.deliverTracked(event)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// deliverTracked wraps deliver with the in-flight counter Flush waits on, so
+// a delivery that's stuck retrying is still accounted for after it's been
+// dequeued.
+func (s *asyncSink) deliverTracked(event sinkEvent) {
+	atomic.AddInt32(&s.inFlight, 1)
+	defer atomic.AddInt32(&s.inFlight, -1)
+
+	s.deliver(event)
+}
+
+// deliver writes event to the inner sink, retrying with exponential backoff
+// until it succeeds or the sink is closed.
+func (s *asyncSink) deliver(event sinkEvent) {
+	backoff := sinkInitialBackoff
+
+	for {
+		err := s.inner.Write(event.level, event.ts, event.appName, event.msg, event.fields)
+
+		if err == nil {
+			return
+		}
+
+		select {
+		case <-s.done:
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+
+		if backoff > sinkMaxBackoff {
+			backoff = sinkMaxBackoff
+		}
+	}
+}
+
+// Flush blocks until the queue is empty and no delivery is in flight, or ctx
+// is done. A delivery that's retrying after a failed write is still
+// in flight, so this won't return early just because the worker has
+// dequeued it.
+func (s *asyncSink) Flush(ctx context.Context) error {
+	for len(s.queue) > 0 || atomic.LoadInt32(&s.inFlight) > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(sinkFlushPollEvery):
+		}
+	}
+
+	return nil
+}
+
+func (s *asyncSink) Close() error {
+	close(s.done)
+	s.wg.Wait()
+
+	return s.inner.Close()
+}
+
+// syslogSink writes events to a syslog server, optionally over TLS. It
+// dials when attached, and after a failed write it reconnects on the next
+// write.
+type syslogSink struct {
+	network   string
+	addr      string
+	tlsConfig *tls.Config
+
+	// tag overrides appName as the syslog tag when set.
+	tag string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func (s *syslogSink) Write(level string, ts time.Time, appName string, msg string, fields map[string]interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		if err := s.connect(); err != nil {
+			return fmt.Errorf("syslog sink: %w", err)
+		}
+	}
+
+	tag := appName
+
+	if s.tag != "" {
+		tag = s.tag
+	}
+
+	line, err := formatSyslogLine(level, ts, tag, msg, fields)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.conn.Write(line); err != nil {
+		s.conn.Close()
+		s.conn = nil
+
+		return fmt.Errorf("syslog sink: %w", err)
+	}
+
+	return nil
+}
+
+func (s *syslogSink) connect() error {
+	if s.tlsConfig != nil {
+		conn, err := tls.Dial(s.network, s.addr, s.tlsConfig)
+
+		if err != nil {
+			return err
+		}
+
+		s.conn = conn
+
+		return nil
+	}
+
+	conn, err := net.Dial(s.network, s.addr)
+
+	if err != nil {
+		return err
+	}
+
+	s.conn = conn
+
+	return nil
+}
+
+func (s *syslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+
+	err := s.conn.Close()
+	s.conn = nil
+
+	return err
+}
+
+// syslogSeverity maps our level names onto RFC 5424 severities, using the
+// "user-level messages" facility (1).
+var syslogSeverity = map[string]int{
+	"debug": 7,
+	"info":  6,
+	"warn":  4,
+	"error": 3,
+}
+
+const syslogFacility = 1
+
+// formatSyslogLine renders a single event as an RFC 3164-style syslog line,
+// with the structured fields appended as a JSON suffix.
+func formatSyslogLine(level string, ts time.Time, appName string, msg string, fields map[string]interface{}) ([]byte, error) {
+	severity, ok := syslogSeverity[level]
+
+	if !ok {
+		severity = syslogSeverity["info"]
+	}
+
+	priority := syslogFacility*8 + severity
+	hostname, _ := os.Hostname()
+
+	line := fmt.Sprintf("<%d>%s %s %s: %s", priority, ts.Format(time.RFC3339), hostname, appName, msg)
+
+	if len(fields) > 0 {
+		encodedFields, err := json.Marshal(fields)
+
+		if err != nil {
+			return nil, fmt.Errorf("syslog sink: failed to encode fields: %w", err)
+		}
+
+		line = line + " " + string(encodedFields)
+	}
+
+	return []byte(line + "\n"), nil
+}
+
+// httpSink POSTs each event as a JSON document to a collector URL.
+type httpSink struct {
+	url    string
+	client *http.Client
+}
+
+func (s *httpSink) Write(level string, ts time.Time, appName string, msg string, fields map[string]interface{}) error {
+	body, err := json.Marshal(sinkEventJSON(level, ts, appName, msg, fields))
+
+	if err != nil {
+		return fmt.Errorf("http sink: %w", err)
+	}
+
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(body))
+
+	if err != nil {
+		return fmt.Errorf("http sink: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("http sink: unexpected status %s", resp.Status)
+	}
+
+	return nil
+}
+
+func (s *httpSink) Close() error {
+	return nil
+}
+
+// writerSink writes each event as a line of JSON to an arbitrary io.Writer.
+type writerSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *writerSink) Write(level string, ts time.Time, appName string, msg string, fields map[string]interface{}) error {
+	body, err := json.Marshal(sinkEventJSON(level, ts, appName, msg, fields))
+
+	if err != nil {
+		return fmt.Errorf("writer sink: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.w.Write(append(body, '\n')); err != nil {
+		return fmt.Errorf("writer sink: %w", err)
+	}
+
+	return nil
+}
+
+func (s *writerSink) Close() error {
+	if closer, ok := s.w.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+// sinkEventJSON builds the common wire representation shared by the HTTP
+// and writer sinks.
+func sinkEventJSON(level string, ts time.Time, appName string, msg string, fields map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"level":   level,
+		"time":    ts,
+		"app":     appName,
+		"message": msg,
+		"meta":    fields,
+	}
+}
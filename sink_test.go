@@ -0,0 +1,200 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithWriterSink(t *testing.T) {
+	var buf bytes.Buffer
+	syncedBuf := &syncWriter{w: &buf}
+
+	testLogger, err := ConfigureLogger("deskpass-logger-test", "", "development", WithWriterSink(syncedBuf))
+
+	if err != nil {
+		t.Fatalf("Failed to configure logger: %v", err)
+	}
+
+	testLogger.Info("This is a test info message for the writer sink", map[string]interface{}{"testKey": "testValue"})
+
+	if err := testLogger.Flush(context.Background()); err != nil {
+		t.Fatalf("Failed to flush logger: %v", err)
+	}
+
+	line := strings.TrimSpace(syncedBuf.String())
+
+	if line == "" {
+		t.Fatal("Expected the writer sink to have received an event")
+	}
+
+	var decoded map[string]interface{}
+
+	if err := json.Unmarshal([]byte(line), &decoded); err != nil {
+		t.Fatalf("Failed to decode sink event: %v", err)
+	}
+
+	if decoded["message"] != "This is a test info message for the writer sink" {
+		t.Fatalf("Unexpected message in sink event: %v", decoded["message"])
+	}
+}
+
+func TestSyslogSinkFormatting(t *testing.T) {
+	line, err := formatSyslogLine("error", time.Unix(0, 0).UTC(), "deskpass-logger-test", "boom", map[string]interface{}{"testKey": "testValue"})
+
+	if err != nil {
+		t.Fatalf("Failed to format syslog line: %v", err)
+	}
+
+	if !strings.Contains(string(line), "boom") || !strings.Contains(string(line), "testKey") {
+		t.Fatalf("Expected formatted line to contain message and fields, got: %s", line)
+	}
+}
+
+func TestAsyncSinkFlushWaitsForInFlightDelivery(t *testing.T) {
+	sink := &failingSink{}
+	async := newAsyncSink(sink)
+
+	if err := async.Write("error", time.Now(), "deskpass-logger-test", "boom", nil); err != nil {
+		t.Fatalf("Failed to queue event: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	if err := async.Flush(ctx); err == nil {
+		t.Fatal("Expected Flush to time out while delivery is still retrying, but it returned success")
+	}
+
+	if atomic.LoadInt32(&sink.attempts) == 0 {
+		t.Fatal("Expected the inner sink to have been attempted at least once")
+	}
+
+	if err := async.Close(); err != nil {
+		t.Fatalf("Failed to close async sink: %v", err)
+	}
+}
+
+func TestWithSyslogSinkTagOverridesAppName(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+
+	sink := &syslogSink{tag: "deskpass-logger-test-production", conn: client}
+	defer sink.Close()
+
+	received := make(chan string, 1)
+
+	go func() {
+		buf := make([]byte, 1024)
+		n, _ := server.Read(buf)
+		received <- string(buf[:n])
+	}()
+
+	if err := sink.Write("info", time.Unix(0, 0).UTC(), "deskpass-logger-test", "boom", nil); err != nil {
+		t.Fatalf("Failed to write to syslog sink: %v", err)
+	}
+
+	line := <-received
+
+	if !strings.Contains(line, "deskpass-logger-test-production:") {
+		t.Fatalf("Expected formatted line to carry the overridden tag, got: %s", line)
+	}
+}
+
+// failingSink fails every write, so deliver keeps retrying,
+// giving TestAsyncSinkFlushWaitsForInFlightDelivery something to observe
+// in flight.
+type failingSink struct {
+	attempts int32
+}
+
+func (s *failingSink) Write(level string, ts time.Time, appName string, msg string, fields map[string]interface{}) error {
+	atomic.AddInt32(&s.attempts, 1)
+
+	return fmt.Errorf("simulated failure")
+}
+
+func (s *failingSink) Close() error {
+	return nil
+}
+
+func TestLoggerClose(t *testing.T) {
+	sink := &closeTrackingSink{}
+
+	testLogger, err := ConfigureLogger("deskpass-logger-test", "", "development", WithSink(sink))
+
+	if err != nil {
+		t.Fatalf("Failed to configure logger: %v", err)
+	}
+
+	if err := testLogger.Close(); err != nil {
+		t.Fatalf("Failed to close logger: %v", err)
+	}
+
+	if !sink.closed {
+		t.Fatal("Expected Logger.Close to close its sinks")
+	}
+}
+
+func TestConfigureLoggerRollsBackSinksOnOptionError(t *testing.T) {
+	sink := &closeTrackingSink{}
+
+	failingOpt := func(l *zerologLogger) error {
+		return fmt.Errorf("simulated option failure")
+	}
+
+	_, err := ConfigureLogger("deskpass-logger-test", "", "development", WithSink(sink), failingOpt)
+
+	if err == nil {
+		t.Fatal("Expected ConfigureLogger to return an error")
+	}
+
+	if !sink.closed {
+		t.Fatal("Expected the sink attached by the preceding option to be closed on rollback")
+	}
+}
+
+// closeTrackingSink records whether Close was called, for tests that check
+// Logger.Close/rollback wiring rather than sink delivery itself.
+type closeTrackingSink struct {
+	closed bool
+}
+
+func (s *closeTrackingSink) Write(level string, ts time.Time, appName string, msg string, fields map[string]interface{}) error {
+	return nil
+}
+
+func (s *closeTrackingSink) Close() error {
+	s.closed = true
+
+	return nil
+}
+
+// syncWriter is a small goroutine-safe io.Writer wrapper so the test can
+// read buf.String() while the async sink's worker goroutine is writing to
+// it.
+type syncWriter struct {
+	mu sync.Mutex
+	w  *bytes.Buffer
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.w.Write(p)
+}
+
+func (s *syncWriter) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.w.String()
+}